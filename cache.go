@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Cache is the translation-cache backend used by Greeter. Implementations
+// must be safe for concurrent use. text and lang together form the cache
+// key; a zero ttl passed to Put means the entry never expires.
+type Cache interface {
+	// Get returns the cached translation of text into lang, if present and
+	// not expired.
+	Get(text, lang string) (string, bool)
+	// Put stores the translation of text into lang, expiring after ttl.
+	Put(text, lang, translation string, ttl time.Duration) error
+	// Flush discards every cached entry.
+	Flush() error
+	// Bytes reports the cache's current size, exposed via Stats.CacheBytes.
+	Bytes() int64
+	// Close releases any resources (connections, file handles) held by the
+	// backend. Callers must call it exactly once, typically from
+	// Greeter.Close.
+	Close() error
+}
+
+// newCache constructs the Cache backend selected by $GREETER_CACHE_BACKEND
+// ("json", "redis", or "sqlite"; defaults to "json"). cacheFile is only used
+// by the json backend.
+func newCache(cacheFile string) (Cache, error) {
+	switch backend := os.Getenv("GREETER_CACHE_BACKEND"); backend {
+	case "", "json":
+		return NewJSONCache(cacheFile)
+	case "redis":
+		addr := os.Getenv("GREETER_REDIS_ADDR")
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		return NewRedisCache(addr), nil
+	case "sqlite":
+		path := os.Getenv("GREETER_SQLITE_PATH")
+		if path == "" {
+			path = "translation_cache.db"
+		}
+		return NewSQLiteCache(path)
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q (want json, redis, or sqlite)", backend)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// jsonCacheEntry is one cached translation plus its optional expiry.
+type jsonCacheEntry struct {
+	Translation string    `json:"translation"`
+	ExpiresAt   time.Time `json:"expiresAt,omitempty"`
+}
+
+// JSONCache is the original Cache backend: a JSON file rewritten in full on
+// every Put. It's fine for a single CLI invocation or a handful of cached
+// phrases, but it doesn't scale past a few hundred entries or survive
+// concurrent server requests well, since every write takes a full-file lock.
+type JSONCache struct {
+	mu      sync.RWMutex
+	path    string
+	entries map[string]map[string]jsonCacheEntry // map[text]map[lang]entry
+}
+
+// NewJSONCache loads path (if it exists) into a new JSONCache.
+func NewJSONCache(path string) (*JSONCache, error) {
+	c := &JSONCache{
+		path:    path,
+		entries: make(map[string]map[string]jsonCacheEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("failed to read cache: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		// Fall back to an empty cache rather than failing startup over a
+		// corrupt or legacy-format cache file.
+		return c, nil
+	}
+
+	return c, nil
+}
+
+func (c *JSONCache) Get(text, lang string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	langs, ok := c.entries[text]
+	if !ok {
+		return "", false
+	}
+	entry, ok := langs[lang]
+	if !ok {
+		return "", false
+	}
+	if !entry.ExpiresAt.IsZero() && timeNow().After(entry.ExpiresAt) {
+		return "", false
+	}
+
+	return entry.Translation, true
+}
+
+func (c *JSONCache) Put(text, lang, translation string, ttl time.Duration) error {
+	c.mu.Lock()
+	if c.entries[text] == nil {
+		c.entries[text] = make(map[string]jsonCacheEntry)
+	}
+	entry := jsonCacheEntry{Translation: translation}
+	if ttl > 0 {
+		entry.ExpiresAt = timeNow().Add(ttl)
+	}
+	c.entries[text][lang] = entry
+	c.mu.Unlock()
+
+	return c.save()
+}
+
+func (c *JSONCache) Flush() error {
+	c.mu.Lock()
+	c.entries = make(map[string]map[string]jsonCacheEntry)
+	c.mu.Unlock()
+
+	return c.save()
+}
+
+// Close is a no-op: JSONCache holds no resources beyond the file it already
+// rewrites on every Put.
+func (c *JSONCache) Close() error {
+	return nil
+}
+
+func (c *JSONCache) Bytes() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+// save rewrites the entire cache file. Callers hold no lock when calling
+// this; it takes its own read lock.
+func (c *JSONCache) save() error {
+	c.mu.RLock()
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	c.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %v", err)
+	}
+
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save cache: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestJSONCacheGetPut tests basic get/put and that entries persist to disk.
+func TestJSONCacheGetPut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	cache, err := NewJSONCache(path)
+	if err != nil {
+		t.Fatalf("NewJSONCache failed: %v", err)
+	}
+
+	if _, ok := cache.Get("hello", "es"); ok {
+		t.Error("expected miss on empty cache")
+	}
+
+	if err := cache.Put("hello", "es", "¡hola!", 0); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	translation, ok := cache.Get("hello", "es")
+	if !ok || translation != "¡hola!" {
+		t.Errorf("Get = %q, %v; want %q, true", translation, ok, "¡hola!")
+	}
+
+	// A second cache instance reloaded from the same path should see it too.
+	reloaded, err := NewJSONCache(path)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if translation, ok := reloaded.Get("hello", "es"); !ok || translation != "¡hola!" {
+		t.Errorf("reloaded Get = %q, %v; want %q, true", translation, ok, "¡hola!")
+	}
+}
+
+// TestJSONCacheTTL tests that entries expire after their TTL elapses.
+func TestJSONCacheTTL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache, err := NewJSONCache(path)
+	if err != nil {
+		t.Fatalf("NewJSONCache failed: %v", err)
+	}
+
+	originalNow := timeNow
+	defer func() { timeNow = originalNow }()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	if err := cache.Put("hello", "es", "¡hola!", time.Minute); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	timeNow = func() time.Time { return now.Add(2 * time.Minute) }
+
+	if _, ok := cache.Get("hello", "es"); ok {
+		t.Error("expected expired entry to miss")
+	}
+}
+
+// TestJSONCacheFlush tests that Flush discards every cached entry.
+func TestJSONCacheFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache, err := NewJSONCache(path)
+	if err != nil {
+		t.Fatalf("NewJSONCache failed: %v", err)
+	}
+
+	if err := cache.Put("hello", "es", "¡hola!", 0); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if _, ok := cache.Get("hello", "es"); ok {
+		t.Error("expected cache to be empty after Flush")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces every key RedisCache writes, so Flush and Bytes
+// can be scoped to the greeter's own entries in a Redis database that may
+// also be shared with other applications.
+const redisKeyPrefix = "greeter:"
+
+// errRedisMiss is returned by redisConn.get for a key that simply isn't
+// present, as opposed to a connection failure or timeout talking to Redis.
+var errRedisMiss = errors.New("redis: key not found")
+
+// redisConn is the subset of *redis.Client behavior RedisCache depends on,
+// narrowed to plain Go types so it can be faked in tests without a real
+// Redis server.
+type redisConn interface {
+	get(ctx context.Context, key string) (string, error)
+	set(ctx context.Context, key, value string, ttl time.Duration) error
+	scanKeys(ctx context.Context, match string) ([]string, error)
+	del(ctx context.Context, keys ...string) error
+	memoryUsage(ctx context.Context, key string) (int64, error)
+	close() error
+}
+
+// goRedisConn adapts *redis.Client to redisConn.
+type goRedisConn struct {
+	client *redis.Client
+}
+
+func (c *goRedisConn) get(ctx context.Context, key string) (string, error) {
+	val, err := c.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", errRedisMiss
+	}
+	return val, err
+}
+
+func (c *goRedisConn) set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+// scanKeys walks the keyspace with SCAN rather than KEYS, so listing the
+// greeter's own keys doesn't block a shared Redis instance on a large
+// database.
+func (c *goRedisConn) scanKeys(ctx context.Context, match string) ([]string, error) {
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := c.client.Scan(ctx, cursor, match, 100).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
+func (c *goRedisConn) del(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
+func (c *goRedisConn) memoryUsage(ctx context.Context, key string) (int64, error) {
+	return c.client.MemoryUsage(ctx, key).Result()
+}
+
+func (c *goRedisConn) close() error {
+	return c.client.Close()
+}
+
+// RedisCache is a Cache backend that stores translations in Redis, so the
+// cache can be shared across multiple RunServer instances instead of each
+// one keeping its own JSON file. Keys are namespaced as
+// "greeter:<lang>:<text>"; TTL is delegated to Redis's own expiry.
+type RedisCache struct {
+	conn redisConn
+	ctx  context.Context
+}
+
+// NewRedisCache connects to a Redis server at addr (e.g. "localhost:6379").
+func NewRedisCache(addr string) *RedisCache {
+	return &RedisCache{
+		conn: &goRedisConn{client: redis.NewClient(&redis.Options{Addr: addr})},
+		ctx:  context.Background(),
+	}
+}
+
+func (c *RedisCache) key(text, lang string) string {
+	return fmt.Sprintf("%s%s:%s", redisKeyPrefix, lang, text)
+}
+
+func (c *RedisCache) Get(text, lang string) (string, bool) {
+	val, err := c.conn.get(c.ctx, c.key(text, lang))
+	if err != nil {
+		if !errors.Is(err, errRedisMiss) {
+			log.Printf("Warning: redis cache lookup failed: %v", err)
+		}
+		return "", false
+	}
+	return val, true
+}
+
+func (c *RedisCache) Put(text, lang, translation string, ttl time.Duration) error {
+	return c.conn.set(c.ctx, c.key(text, lang), translation, ttl)
+}
+
+// Flush discards only this cache's own "greeter:*" keys, leaving the rest
+// of a Redis database that may be shared with other applications untouched.
+func (c *RedisCache) Flush() error {
+	keys, err := c.conn.scanKeys(c.ctx, redisKeyPrefix+"*")
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.conn.del(c.ctx, keys...)
+}
+
+// Bytes sums MEMORY USAGE across every "greeter:*" key. "greeter" itself is
+// never a real key, so querying it directly (as earlier versions of this
+// backend did) always reported 0.
+func (c *RedisCache) Bytes() int64 {
+	keys, err := c.conn.scanKeys(c.ctx, redisKeyPrefix+"*")
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, k := range keys {
+		size, err := c.conn.memoryUsage(c.ctx, k)
+		if err != nil {
+			continue
+		}
+		total += size
+	}
+	return total
+}
+
+// Close closes the underlying Redis client connection.
+func (c *RedisCache) Close() error {
+	return c.conn.close()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRedisConn is an in-memory stand-in for a real Redis server, letting
+// RedisCache be tested without one.
+type fakeRedisConn struct {
+	values map[string]string
+	sizes  map[string]int64
+	getErr error // if set, get always fails with this instead of a miss
+	closed bool
+}
+
+func newFakeRedisConn() *fakeRedisConn {
+	return &fakeRedisConn{values: make(map[string]string), sizes: make(map[string]int64)}
+}
+
+func (f *fakeRedisConn) get(_ context.Context, key string) (string, error) {
+	if f.getErr != nil {
+		return "", f.getErr
+	}
+	v, ok := f.values[key]
+	if !ok {
+		return "", errRedisMiss
+	}
+	return v, nil
+}
+
+func (f *fakeRedisConn) set(_ context.Context, key, value string, _ time.Duration) error {
+	f.values[key] = value
+	f.sizes[key] = int64(len(value))
+	return nil
+}
+
+func (f *fakeRedisConn) scanKeys(_ context.Context, match string) ([]string, error) {
+	prefix := strings.TrimSuffix(match, "*")
+	var keys []string
+	for k := range f.values {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (f *fakeRedisConn) del(_ context.Context, keys ...string) error {
+	for _, k := range keys {
+		delete(f.values, k)
+		delete(f.sizes, k)
+	}
+	return nil
+}
+
+func (f *fakeRedisConn) memoryUsage(_ context.Context, key string) (int64, error) {
+	size, ok := f.sizes[key]
+	if !ok {
+		return 0, errRedisMiss
+	}
+	return size, nil
+}
+
+func (f *fakeRedisConn) close() error {
+	f.closed = true
+	return nil
+}
+
+func newTestRedisCache() (*RedisCache, *fakeRedisConn) {
+	conn := newFakeRedisConn()
+	return &RedisCache{conn: conn, ctx: context.Background()}, conn
+}
+
+// TestRedisCacheGetPut tests basic get/put.
+func TestRedisCacheGetPut(t *testing.T) {
+	cache, _ := newTestRedisCache()
+
+	if _, ok := cache.Get("hello", "es"); ok {
+		t.Error("expected miss on empty cache")
+	}
+
+	if err := cache.Put("hello", "es", "¡hola!", 0); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	translation, ok := cache.Get("hello", "es")
+	if !ok || translation != "¡hola!" {
+		t.Errorf("Get = %q, %v; want %q, true", translation, ok, "¡hola!")
+	}
+}
+
+// TestRedisCacheFlushScopesToPrefix tests that Flush only discards the
+// greeter's own "greeter:*" keys, not the whole database.
+func TestRedisCacheFlushScopesToPrefix(t *testing.T) {
+	cache, conn := newTestRedisCache()
+
+	if err := cache.Put("hello", "es", "¡hola!", 0); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	// A key belonging to some other application sharing the same database.
+	conn.values["other-app:session:1"] = "keep-me"
+
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if _, ok := cache.Get("hello", "es"); ok {
+		t.Error("expected cache entry to be gone after Flush")
+	}
+	if _, ok := conn.values["other-app:session:1"]; !ok {
+		t.Error("Flush must not touch keys outside the greeter: namespace")
+	}
+}
+
+// TestRedisCacheBytes tests that Bytes sums usage across every cached entry
+// rather than querying the nonexistent literal key "greeter".
+func TestRedisCacheBytes(t *testing.T) {
+	cache, _ := newTestRedisCache()
+
+	if got := cache.Bytes(); got != 0 {
+		t.Errorf("expected 0 bytes for empty cache, got %d", got)
+	}
+
+	if err := cache.Put("hello", "es", "¡hola!", 0); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := cache.Put("hi", "fr", "salut", 0); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	want := int64(len("¡hola!") + len("salut"))
+	if got := cache.Bytes(); got != want {
+		t.Errorf("Bytes() = %d, want %d", got, want)
+	}
+}
+
+// TestRedisCacheGetConnectionError tests that a connection failure is still
+// reported as a miss to the caller (so translation proceeds via the API),
+// distinct from errRedisMiss internally.
+func TestRedisCacheGetConnectionError(t *testing.T) {
+	cache, conn := newTestRedisCache()
+	conn.getErr = errors.New("dial tcp: connection refused")
+
+	if _, ok := cache.Get("hello", "es"); ok {
+		t.Error("expected miss when the connection fails")
+	}
+}
+
+// TestRedisCacheClose tests that Close releases the underlying connection.
+func TestRedisCacheClose(t *testing.T) {
+	cache, conn := newTestRedisCache()
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !conn.closed {
+		t.Error("expected Close to close the underlying connection")
+	}
+}
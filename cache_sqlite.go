@@ -0,0 +1,86 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteCache is a Cache backend backed by a local SQLite database. It's a
+// middle ground between the JSON file backend, which doesn't scale past a
+// few hundred entries, and Redis, which requires a separate server to run.
+type SQLiteCache struct {
+	db *sql.DB
+}
+
+// NewSQLiteCache opens (creating if necessary) a SQLite cache database at
+// path.
+func NewSQLiteCache(path string) (*SQLiteCache, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite cache: %v", err)
+	}
+
+	schema := `CREATE TABLE IF NOT EXISTS translations (
+		text TEXT NOT NULL,
+		lang TEXT NOT NULL,
+		translation TEXT NOT NULL,
+		expires_at INTEGER,
+		PRIMARY KEY (text, lang)
+	)`
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create sqlite schema: %v", err)
+	}
+
+	return &SQLiteCache{db: db}, nil
+}
+
+func (c *SQLiteCache) Get(text, lang string) (string, bool) {
+	var translation string
+	var expiresAt sql.NullInt64
+
+	row := c.db.QueryRow(`SELECT translation, expires_at FROM translations WHERE text = ? AND lang = ?`, text, lang)
+	if err := row.Scan(&translation, &expiresAt); err != nil {
+		return "", false
+	}
+	if expiresAt.Valid && timeNow().Unix() > expiresAt.Int64 {
+		return "", false
+	}
+
+	return translation, true
+}
+
+func (c *SQLiteCache) Put(text, lang, translation string, ttl time.Duration) error {
+	var expiresAt any
+	if ttl > 0 {
+		expiresAt = timeNow().Add(ttl).Unix()
+	}
+
+	_, err := c.db.Exec(`INSERT INTO translations (text, lang, translation, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(text, lang) DO UPDATE SET translation = excluded.translation, expires_at = excluded.expires_at`,
+		text, lang, translation, expiresAt)
+	return err
+}
+
+func (c *SQLiteCache) Flush() error {
+	_, err := c.db.Exec(`DELETE FROM translations`)
+	return err
+}
+
+// Close closes the underlying SQLite database handle.
+func (c *SQLiteCache) Close() error {
+	return c.db.Close()
+}
+
+func (c *SQLiteCache) Bytes() int64 {
+	var pageCount, pageSize int64
+	if err := c.db.QueryRow(`PRAGMA page_count`).Scan(&pageCount); err != nil {
+		return 0
+	}
+	if err := c.db.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0
+	}
+	return pageCount * pageSize
+}
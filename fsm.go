@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// State is a stage in a single greeting request's lifecycle.
+type State int
+
+const (
+	Idle State = iota
+	Resolving
+	CacheLookup
+	Translating
+	Cached
+	Done
+	Cancelled
+	Failed
+)
+
+func (s State) String() string {
+	switch s {
+	case Idle:
+		return "Idle"
+	case Resolving:
+		return "Resolving"
+	case CacheLookup:
+		return "CacheLookup"
+	case Translating:
+		return "Translating"
+	case Cached:
+		return "Cached"
+	case Done:
+		return "Done"
+	case Cancelled:
+		return "Cancelled"
+	case Failed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// validTransitions maps each state to the states it may legally move into.
+// Idle is the entry point; Done, Cancelled, and Failed are terminal. A
+// greeting that's already cached skips Translating entirely.
+var validTransitions = map[State][]State{
+	Idle:        {Resolving, Cancelled, Failed},
+	Resolving:   {CacheLookup, Cancelled, Failed},
+	CacheLookup: {Translating, Cached, Cancelled, Failed},
+	Translating: {Cached, Cancelled, Failed},
+	Cached:      {Done, Cancelled, Failed},
+	Done:        {},
+	Cancelled:   {},
+	Failed:      {},
+}
+
+// Transition is one state change of a GreetFSM, emitted on its Events
+// channel so callers (e.g. the /api/greet/stream SSE handler) can observe
+// a greeting request's progress live.
+type Transition struct {
+	From State
+	To   State
+	Err  error
+}
+
+// MarshalJSON renders a Transition for SSE/JSON consumers as plain strings
+// rather than the underlying State ints.
+func (t Transition) MarshalJSON() ([]byte, error) {
+	var errMsg string
+	if t.Err != nil {
+		errMsg = t.Err.Error()
+	}
+	return json.Marshal(struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Error string `json:"error,omitempty"`
+	}{From: t.From.String(), To: t.To.String(), Error: errMsg})
+}
+
+// GreetFSM drives one Greet(ctx) call through Idle -> Resolving ->
+// CacheLookup -> [Translating] -> Cached -> Done, rejecting any transition
+// not listed in validTransitions. Result and Err are only meaningful once
+// Events has been closed.
+type GreetFSM struct {
+	state  State
+	Events chan Transition
+	Result string
+	Err    error
+}
+
+// NewGreetFSM creates a GreetFSM in the Idle state.
+func NewGreetFSM() *GreetFSM {
+	return &GreetFSM{
+		state:  Idle,
+		Events: make(chan Transition, 8),
+	}
+}
+
+// State returns the FSM's current state.
+func (f *GreetFSM) State() State {
+	return f.state
+}
+
+// transition moves the FSM to next if that move is listed in
+// validTransitions, emitting the Transition on Events. It returns an error
+// and leaves the state unchanged otherwise.
+func (f *GreetFSM) transition(next State, err error) error {
+	for _, allowed := range validTransitions[f.state] {
+		if allowed == next {
+			f.emit(next, err)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid transition from %s to %s", f.state, next)
+}
+
+// emit moves the FSM to next unconditionally and sends the Transition on
+// Events. Used for the terminal Cancelled/Failed states, which must always
+// be reachable regardless of where the FSM currently is.
+func (f *GreetFSM) emit(next State, err error) {
+	f.Events <- Transition{From: f.state, To: next, Err: err}
+	f.state = next
+}
+
+// fail moves the FSM to Failed and records err as the run's result.
+func (f *GreetFSM) fail(err error) {
+	f.emit(Failed, err)
+	f.Err = err
+}
+
+// cancel moves the FSM to Cancelled and records err (ctx.Err()) as the run's
+// result.
+func (f *GreetFSM) cancel(err error) {
+	f.emit(Cancelled, err)
+	f.Err = err
+}
+
+// run executes one full greeting lifecycle for g, closing Events when it
+// finishes, whether that's success, cancellation, or failure.
+func (f *GreetFSM) run(ctx context.Context, g *Greeter) {
+	defer close(f.Events)
+
+	if err := f.transition(Resolving, nil); err != nil {
+		f.fail(err)
+		return
+	}
+	if ctx.Err() != nil {
+		f.cancel(ctx.Err())
+		return
+	}
+
+	greeting := g.getTimeBasedGreeting()
+
+	if err := f.transition(CacheLookup, nil); err != nil {
+		f.fail(err)
+		return
+	}
+	if ctx.Err() != nil {
+		f.cancel(ctx.Err())
+		return
+	}
+
+	targetLang := string(g.language)
+	if translation, ok := g.cachedGreeting(greeting, targetLang); ok {
+		greeting = translation
+	} else if targetLang != "en" {
+		g.stats.CacheMisses++
+		if err := f.transition(Translating, nil); err != nil {
+			f.fail(err)
+			return
+		}
+
+		start := timeNow()
+		translated, err := g.translateGreetingCtx(ctx, greeting)
+		if err != nil {
+			if ctx.Err() != nil {
+				f.cancel(ctx.Err())
+				return
+			}
+			f.fail(err)
+			return
+		}
+		translationLatencySeconds.WithLabelValues(targetLang).Observe(timeNow().Sub(start).Seconds())
+		greeting = translated
+	}
+
+	if err := f.transition(Cached, nil); err != nil {
+		f.fail(err)
+		return
+	}
+	if ctx.Err() != nil {
+		f.cancel(ctx.Err())
+		return
+	}
+
+	if err := f.transition(Done, nil); err != nil {
+		f.fail(err)
+		return
+	}
+
+	f.Result = greeting
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// TestGreetFSMHappyPath drives a full Idle -> ... -> Done run and checks
+// that State() reflects the final transition and Result is populated.
+func TestGreetFSMHappyPath(t *testing.T) {
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
+	g.SetLanguage("es")
+
+	fsm := g.GreetStream(context.Background())
+	var states []State
+	for t := range fsm.Events {
+		states = append(states, t.To)
+	}
+
+	if fsm.State() != Done {
+		t.Errorf("expected final state Done, got %s", fsm.State())
+	}
+	if fsm.Err != nil {
+		t.Errorf("unexpected error: %v", fsm.Err)
+	}
+	if fsm.Result == "" {
+		t.Error("expected non-empty Result")
+	}
+
+	want := []State{Resolving, CacheLookup, Translating, Cached, Done}
+	if len(states) != len(want) {
+		t.Fatalf("expected %d transitions, got %d: %v", len(want), len(states), states)
+	}
+	for i, s := range want {
+		if states[i] != s {
+			t.Errorf("transition %d: expected %s, got %s", i, s, states[i])
+		}
+	}
+}
+
+// TestGreetFSMInvalidTransition rejects a move not listed in
+// validTransitions and leaves the FSM's state unchanged.
+func TestGreetFSMInvalidTransition(t *testing.T) {
+	fsm := NewGreetFSM()
+
+	if err := fsm.transition(Done, nil); err == nil {
+		t.Error("expected error transitioning Idle -> Done directly, got none")
+	}
+	if fsm.State() != Idle {
+		t.Errorf("expected state to remain Idle after rejected transition, got %s", fsm.State())
+	}
+}
+
+// TestGreetFSMCancellation checks that a pre-cancelled context moves the
+// FSM to Cancelled instead of running the full lifecycle.
+func TestGreetFSMCancellation(t *testing.T) {
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
+	g.SetLanguage("es")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fsm := g.GreetStream(ctx)
+	for range fsm.Events {
+	}
+
+	if fsm.State() != Cancelled {
+		t.Errorf("expected final state Cancelled, got %s", fsm.State())
+	}
+	if fsm.Err == nil {
+		t.Error("expected Err to be set on cancellation")
+	}
+}
@@ -2,36 +2,51 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
-	"sync"
 	"time"
 
 	translate "cloud.google.com/go/translate/apiv3"
 	translatepb "cloud.google.com/go/translate/apiv3/translatepb"
 	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
 )
 
+// transientCodes are gRPC status codes worth retrying with backoff: the
+// server is temporarily unavailable, the call timed out, or we're being
+// rate-limited.
+var transientCodes = []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted}
+
+// retryCallOptions returns the gax retry policy applied to every
+// TranslateText call, retrying transientCodes with exponential backoff.
+func retryCallOptions() []gax.CallOption {
+	return []gax.CallOption{
+		gax.WithRetry(func() gax.Retryer {
+			return gax.OnCodes(transientCodes, gax.Backoff{
+				Initial:    100 * time.Millisecond,
+				Max:        2 * time.Second,
+				Multiplier: 2,
+			})
+		}),
+	}
+}
+
 // TranslationClient interface for mocking in tests
 type TranslationClient interface {
 	TranslateText(context.Context, *translatepb.TranslateTextRequest, ...gax.CallOption) (*translatepb.TranslateTextResponse, error)
+	DetectLanguage(context.Context, *translatepb.DetectLanguageRequest, ...gax.CallOption) (*translatepb.DetectLanguageResponse, error)
 	Close() error
 }
 
-// TranslationCache represents the cache structure
-type TranslationCache struct {
-	Translations map[string]map[string]string // map[sourceText]map[targetLang]translatedText
-	mu           sync.RWMutex
-}
-
 // Stats tracks translation statistics
 type Stats struct {
 	APICalls     int     `json:"apiCalls"`     // Number of API calls made
 	CharsSent    int     `json:"charsSent"`    // Number of characters sent to API
 	CostEstimate float64 `json:"costEstimate"` // Estimated cost in USD
 	CacheHits    int     `json:"cacheHits"`    // Number of cache hits
+	CacheMisses  int     `json:"cacheMisses"`  // Number of cache misses
+	CacheBytes   int64   `json:"cacheBytes"`   // Size of the cache backend, in bytes
 }
 
 // Language represents ISO 639-1 language codes
@@ -40,16 +55,23 @@ type Language string
 // timeNow allows overriding time.Now in tests
 var timeNow = time.Now
 
+// maxBatchContents is the maximum number of uncached texts sent in a single
+// TranslateText call. Keeps each request well under the Translate v3 API's
+// per-call content limits.
+const maxBatchContents = 100
+
 // Greeter manages greetings in different languages
 type Greeter struct {
-	recipient string
-	language  Language
-	client    TranslationClient
-	ctx       context.Context
-	cache     *TranslationCache
-	cacheFile string
-	projectID string
-	stats     *Stats
+	recipient  string
+	language   Language
+	client     TranslationClient
+	ctx        context.Context
+	cache      Cache
+	cacheTTL   time.Duration
+	projectID  string
+	glossary   string
+	autoSource bool
+	stats      *Stats
 }
 
 // NewGreeter creates a new Greeter with default English language
@@ -72,15 +94,16 @@ func NewGreeter(recipient string) (*Greeter, error) {
 		return nil, fmt.Errorf("failed to create translate client: %v", err)
 	}
 
-	cache := &TranslationCache{
-		Translations: make(map[string]map[string]string),
+	cache, err := newCache("translation_cache.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache: %v", err)
 	}
 
-	// Try to load existing cache
-	cacheFile := "translation_cache.json"
-	if data, err := os.ReadFile(cacheFile); err == nil {
-		if err := json.Unmarshal(data, &cache.Translations); err != nil {
-			log.Printf("Warning: Could not load cache: %v", err)
+	var cacheTTL time.Duration
+	if ttl := os.Getenv("GREETER_CACHE_TTL"); ttl != "" {
+		cacheTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GREETER_CACHE_TTL: %v", err)
 		}
 	}
 
@@ -90,36 +113,70 @@ func NewGreeter(recipient string) (*Greeter, error) {
 		client:    client,
 		ctx:       ctx,
 		cache:     cache,
-		cacheFile: cacheFile,
+		cacheTTL:  cacheTTL,
 		projectID: projectID,
+		glossary:  os.Getenv("GOOGLE_TRANSLATE_GLOSSARY"),
 		stats:     &Stats{}, // Initialize with zero values
 	}, nil
 }
 
-// saveCache saves the translation cache to disk
-func (g *Greeter) saveCache() error {
-	g.cache.mu.RLock()
-	defer g.cache.mu.RUnlock()
+// SetLanguage changes the greeting language
+func (g *Greeter) SetLanguage(lang Language) {
+	g.language = lang
+}
 
-	data, err := json.MarshalIndent(g.cache.Translations, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal cache: %v", err)
-	}
+// SetGlossary sets the resource name of a Translate v3 glossary (e.g.
+// "projects/p/locations/l/glossaries/g") to apply to subsequent translations,
+// keeping names and product terms untranslated.
+func (g *Greeter) SetGlossary(glossary string) {
+	g.glossary = glossary
+}
 
-	if err := os.WriteFile(g.cacheFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to save cache: %v", err)
+// glossaryConfig builds the GlossaryConfig for a translation request, or nil
+// if no glossary has been configured.
+func (g *Greeter) glossaryConfig() *translatepb.TranslateTextGlossaryConfig {
+	if g.glossary == "" {
+		return nil
 	}
+	return &translatepb.TranslateTextGlossaryConfig{Glossary: g.glossary}
+}
 
-	return nil
+// SetAutoSource toggles AutoSource mode. When enabled, translateBatch omits
+// SourceLanguageCode from its TranslateText requests and lets the API detect
+// the source language instead of assuming English. Translations are then
+// cached under the detected source language rather than plain text, since
+// the same greeting arriving from two different actual source languages
+// would otherwise collide on one cache entry and serve the wrong result.
+func (g *Greeter) SetAutoSource(auto bool) {
+	g.autoSource = auto
 }
 
-// SetLanguage changes the greeting language
-func (g *Greeter) SetLanguage(lang Language) {
-	g.language = lang
+// DetectSourceLanguage calls the Translate v3 DetectLanguage RPC to identify
+// the language of text, returning the best guess and its confidence score.
+func (g *Greeter) DetectSourceLanguage(text string) (Language, float32, error) {
+	req := &translatepb.DetectLanguageRequest{
+		Parent:   fmt.Sprintf("projects/%s", g.projectID),
+		MimeType: "text/plain",
+		Source:   &translatepb.DetectLanguageRequest_Content{Content: text},
+	}
+
+	resp, err := g.client.DetectLanguage(g.ctx, req)
+	if err != nil {
+		return "", 0, fmt.Errorf("language detection failed: %v", err)
+	}
+
+	languages := resp.GetLanguages()
+	if len(languages) == 0 {
+		return "", 0, fmt.Errorf("no language detected")
+	}
+
+	best := languages[0]
+	return Language(best.GetLanguageCode()), best.GetConfidence(), nil
 }
 
-// getTimeBasedGreeting returns appropriate greeting based on time of day
-func (g *Greeter) getTimeBasedGreeting() string {
+// timeBasedGreeting returns the appropriate greeting for recipient based on
+// the time of day.
+func timeBasedGreeting(recipient string) string {
 	hour := timeNow().Hour()
 	var greeting string
 
@@ -134,86 +191,187 @@ func (g *Greeter) getTimeBasedGreeting() string {
 		greeting = "Good night"
 	}
 
-	return fmt.Sprintf("%s, %s!", greeting, g.recipient)
+	return fmt.Sprintf("%s, %s!", greeting, recipient)
 }
 
-// translateGreeting translates the greeting to the target language
-func (g *Greeter) translateGreeting(text string) (string, error) {
-	targetLang := string(g.language)
+// getTimeBasedGreeting returns appropriate greeting based on time of day
+func (g *Greeter) getTimeBasedGreeting() string {
+	return timeBasedGreeting(g.recipient)
+}
 
-	// Check cache first
-	g.cache.mu.RLock()
-	if langCache, ok := g.cache.Translations[text]; ok {
-		if translation, ok := langCache[targetLang]; ok {
-			g.stats.CacheHits++
-			g.cache.mu.RUnlock()
-			return translation, nil
-		}
+// cachedGreeting returns greeting already resolved from g's built-in English
+// templates or the translation cache, recording the hit in Stats.
+func (g *Greeter) cachedGreeting(text, targetLang string) (string, bool) {
+	if targetLang == "en" {
+		g.stats.CacheHits++
+		return text, true
+	}
+	// AutoSource mode keys the cache by the source language TranslateText
+	// detects (see translateBatch), which isn't known until the call
+	// returns, so there's nothing to look up yet.
+	if g.autoSource {
+		return "", false
 	}
-	g.cache.mu.RUnlock()
+	if translation, ok := g.cache.Get(text, targetLang); ok {
+		g.stats.CacheHits++
+		return translation, true
+	}
+	return "", false
+}
 
-	// If not in cache, translate using API
+// translateGreetingCtx translates text into g.language, bounded by ctx and
+// retrying transient gRPC errors with exponential backoff. Callers (the
+// GreetFSM's Translating state) are responsible for the cache lookup
+// beforehand.
+func (g *Greeter) translateGreetingCtx(ctx context.Context, text string) (string, error) {
+	translations, err := g.translateBatch(ctx, []string{text}, string(g.language))
+	if err != nil {
+		return "", err
+	}
+	return translations[0], nil
+}
+
+// translateBatch sends a single TranslateText call, bounded by ctx, for one
+// or more uncached texts, updates Stats once for the call, and caches every
+// result under targetLang.
+func (g *Greeter) translateBatch(ctx context.Context, texts []string, targetLang string) ([]string, error) {
 	g.stats.APICalls++
-	g.stats.CharsSent += len(text)
-	g.stats.CostEstimate += float64(len(text)) * 0.00002 // $0.00002 per character
+	for _, text := range texts {
+		g.stats.CharsSent += len(text)
+		g.stats.CostEstimate += float64(len(text)) * 0.00002 // $0.00002 per character
+	}
 
-	log.Printf("Translating text to %s", targetLang)
+	log.Printf("Translating %d text(s) to %s", len(texts), targetLang)
 
 	req := &translatepb.TranslateTextRequest{
-		Contents:           []string{text},
+		Contents:           texts,
 		TargetLanguageCode: targetLang,
-		SourceLanguageCode: "en",
 		MimeType:           "text/plain",
 		Parent:             fmt.Sprintf("projects/%s", g.projectID),
+		GlossaryConfig:     g.glossaryConfig(),
+	}
+	if !g.autoSource {
+		req.SourceLanguageCode = "en"
 	}
 
-	resp, err := g.client.TranslateText(g.ctx, req)
+	resp, err := g.client.TranslateText(ctx, req, retryCallOptions()...)
 	if err != nil {
-		return "", fmt.Errorf("translation failed: %v", err)
+		return nil, fmt.Errorf("translation failed: %v", err)
 	}
 
-	if len(resp.GetTranslations()) == 0 {
-		return "", fmt.Errorf("no translation returned")
+	if len(resp.GetTranslations()) != len(texts) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(texts), len(resp.GetTranslations()))
 	}
 
-	translation := resp.GetTranslations()[0].GetTranslatedText()
+	translations := make([]string, len(texts))
+	for i, t := range resp.GetTranslations() {
+		translation := t.GetTranslatedText()
+		translations[i] = translation
+
+		// In AutoSource mode, fold the detected source language into the
+		// cache key so the same text translated from two different actual
+		// source languages doesn't collide on one entry.
+		cacheText := texts[i]
+		if g.autoSource {
+			cacheText = t.GetDetectedLanguageCode() + "|" + texts[i]
+		}
+		if err := g.cache.Put(cacheText, targetLang, translation, g.cacheTTL); err != nil {
+			log.Printf("Warning: Failed to save cache: %v", err)
+		}
+	}
+	g.stats.CacheBytes = g.cache.Bytes()
+
+	return translations, nil
+}
 
-	// Add to cache
-	g.cache.mu.Lock()
-	if g.cache.Translations[text] == nil {
-		g.cache.Translations[text] = make(map[string]string)
+// GreetMany returns a time-based greeting for each recipient translated into
+// lang, batching every uncached greeting into as few TranslateText calls as
+// possible (up to maxBatchContents texts per call) and reconstructing the
+// original order. This is far cheaper than calling Greet once per recipient
+// when greeting many people at once.
+func (g *Greeter) GreetMany(recipients []string, lang Language) ([]string, error) {
+	targetLang := string(lang)
+	texts := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		texts[i] = timeBasedGreeting(recipient)
 	}
-	g.cache.Translations[text][targetLang] = translation
-	g.cache.mu.Unlock()
 
-	// Save cache to disk
-	if err := g.saveCache(); err != nil {
-		log.Printf("Warning: Failed to save cache: %v", err)
+	greetings := make([]string, len(recipients))
+
+	if targetLang == "en" {
+		for i := range texts {
+			greetings[i] = texts[i]
+			g.stats.CacheHits++
+		}
+		return greetings, nil
 	}
 
-	return translation, nil
-}
+	var pending []string
+	var pendingIdx []int
+
+	for i, text := range texts {
+		// See cachedGreeting: AutoSource mode can't look up a cache entry
+		// before translating, since the key depends on the detected source.
+		if !g.autoSource {
+			if translation, ok := g.cache.Get(text, targetLang); ok {
+				greetings[i] = translation
+				g.stats.CacheHits++
+				continue
+			}
+		}
+		g.stats.CacheMisses++
+		pending = append(pending, text)
+		pendingIdx = append(pendingIdx, i)
+	}
 
-// Greet returns a greeting in the current language
-func (g *Greeter) Greet() (string, error) {
-	greeting := g.getTimeBasedGreeting()
+	for start := 0; start < len(pending); start += maxBatchContents {
+		end := start + maxBatchContents
+		if end > len(pending) {
+			end = len(pending)
+		}
 
-	if string(g.language) != "en" {
-		translated, err := g.translateGreeting(greeting)
+		translations, err := g.translateBatch(g.ctx, pending[start:end], targetLang)
 		if err != nil {
-			return "", fmt.Errorf("translation failed: %v", err)
+			return nil, err
+		}
+
+		for j, translation := range translations {
+			greetings[pendingIdx[start+j]] = translation
 		}
-		greeting = translated
-	} else {
-		// Count English as a cache hit since we're using our built-in English templates
-		g.stats.CacheHits++
 	}
 
-	return greeting, nil
+	return greetings, nil
 }
 
-// Close cleans up resources used by the Greeter
+// Greet returns a greeting in the current language, driven through a
+// GreetFSM (Idle -> Resolving -> CacheLookup -> [Translating] -> Cached ->
+// Done). ctx cancels the request at any transition boundary; transient
+// gRPC errors from the Translate API are retried with backoff before the
+// FSM moves to Failed. Use GreetStream instead to observe the FSM's
+// transitions as they happen.
+func (g *Greeter) Greet(ctx context.Context) (string, error) {
+	fsm := g.GreetStream(ctx)
+	for range fsm.Events {
+	}
+	return fsm.Result, fsm.Err
+}
+
+// GreetStream starts a greeting request's FSM in the background and returns
+// it immediately so callers (e.g. the /api/greet/stream SSE handler) can
+// range over fsm.Events as transitions happen. fsm.Result and fsm.Err are
+// only valid once Events has been closed.
+func (g *Greeter) GreetStream(ctx context.Context) *GreetFSM {
+	fsm := NewGreetFSM()
+	go fsm.run(ctx, g)
+	return fsm
+}
+
+// Close cleans up resources used by the Greeter, including the translate
+// client and the cache backend's own connections/file handles.
 func (g *Greeter) Close() error {
+	if err := g.cache.Close(); err != nil {
+		log.Printf("Warning: failed to close cache: %v", err)
+	}
 	return g.client.Close()
 }
 
@@ -221,12 +379,19 @@ func (g *Greeter) Close() error {
 func RunCLI() {
 	log.SetFlags(log.Ltime) // Only show time in logs
 
-	// Validate command line arguments
-	if len(os.Args) != 3 {
-		log.Fatal("Usage: greeter <recipient> <language-code>")
+	// Validate command line arguments. The language code is optional: when
+	// omitted, it's detected from $LC_ALL/$LANG.
+	if len(os.Args) != 2 && len(os.Args) != 3 {
+		log.Fatal("Usage: greeter <recipient> [language-code]")
 	}
 	recipient := os.Args[1]
-	lang := Language(os.Args[2])
+
+	var lang Language
+	if len(os.Args) == 3 {
+		lang = Language(os.Args[2])
+	} else {
+		lang = DetectLocaleFromEnv()
+	}
 
 	// Create a new greeter
 	greeter, err := NewGreeter(recipient)
@@ -237,7 +402,7 @@ func RunCLI() {
 
 	// Set language and get greeting
 	greeter.SetLanguage(lang)
-	greeting, err := greeter.Greet()
+	greeting, err := greeter.Greet(context.Background())
 	if err != nil {
 		log.Fatalf("Error greeting in %s: %v", lang, err)
 	}
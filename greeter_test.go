@@ -2,9 +2,9 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,8 +16,6 @@ import (
 // mockTranslationClient implements a fake translation client for testing
 type mockTranslationClient struct {
 	translate.TranslationClient
-	// Mock responses for different languages
-	translations map[string]string
 	// For testing error conditions
 	shouldError bool
 }
@@ -27,28 +25,48 @@ func (m *mockTranslationClient) TranslateText(_ context.Context, req *translatep
 		return nil, errors.New("mock translation error")
 	}
 
-	text := req.GetContents()[0]
 	lang := req.GetTargetLanguageCode()
 
-	// Simple mock translations
-	if m.translations == nil {
-		m.translations = map[string]string{
-			"es": "¡" + text + "!",
-			"fr": text + " !",
-			"ja": text + "！",
-			"de": text + "!",
+	// Return error for invalid language code
+	switch lang {
+	case "es", "fr", "ja", "de":
+	default:
+		return nil, errors.New("unsupported language code")
+	}
+
+	translations := make([]*translatepb.Translation, len(req.GetContents()))
+	for i, text := range req.GetContents() {
+		var translation string
+		switch lang {
+		case "es":
+			translation = "¡" + text + "!"
+		case "fr":
+			translation = text + " !"
+		case "ja":
+			translation = text + "！"
+		case "de":
+			translation = text + "!"
 		}
+		translations[i] = &translatepb.Translation{TranslatedText: translation}
 	}
 
-	// Return error for invalid language code
-	if _, ok := m.translations[lang]; !ok {
-		return nil, errors.New("unsupported language code")
+	return &translatepb.TranslateTextResponse{Translations: translations}, nil
+}
+
+func (m *mockTranslationClient) DetectLanguage(_ context.Context, req *translatepb.DetectLanguageRequest, _ ...gax.CallOption) (*translatepb.DetectLanguageResponse, error) {
+	if m.shouldError {
+		return nil, errors.New("mock detection error")
+	}
+
+	content := req.GetContent()
+	lang := "en"
+	if strings.ContainsAny(content, "¡！") {
+		lang = "es"
 	}
 
-	translation := m.translations[lang]
-	return &translatepb.TranslateTextResponse{
-		Translations: []*translatepb.Translation{
-			{TranslatedText: translation},
+	return &translatepb.DetectLanguageResponse{
+		Languages: []*translatepb.DetectedLanguage{
+			{LanguageCode: lang, Confidence: 0.99},
 		},
 	}, nil
 }
@@ -58,18 +76,20 @@ func (m *mockTranslationClient) Close() error {
 }
 
 // Helper function to create a test greeter
-func newTestGreeter(recipient string, client TranslationClient) *Greeter {
-	tmpfile, _ := os.CreateTemp("", "translation_cache_*.json")
+func newTestGreeter(t *testing.T, recipient string, client TranslationClient) *Greeter {
+	t.Helper()
+
+	cache, err := NewJSONCache(filepath.Join(t.TempDir(), "translation_cache.json"))
+	if err != nil {
+		t.Fatalf("failed to create test cache: %v", err)
+	}
 
 	return &Greeter{
 		recipient: recipient,
 		language:  "en",
 		ctx:       context.Background(),
 		client:    client,
-		cache: &TranslationCache{
-			Translations: make(map[string]map[string]string),
-		},
-		cacheFile: tmpfile.Name(),
+		cache:     cache,
 		projectID: "test-project",
 		stats:     &Stats{}, // Initialize stats
 	}
@@ -77,11 +97,10 @@ func newTestGreeter(recipient string, client TranslationClient) *Greeter {
 
 // TestGreeterBasicFunctionality tests the core greeting functionality
 func TestGreeterBasicFunctionality(t *testing.T) {
-	g := newTestGreeter("Test", &mockTranslationClient{})
-	defer os.Remove(g.cacheFile)
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
 
 	// Test English greeting (no translation needed)
-	greeting, err := g.Greet()
+	greeting, err := g.Greet(context.Background())
 	if err != nil {
 		t.Errorf("English greeting failed: %v", err)
 	}
@@ -91,7 +110,7 @@ func TestGreeterBasicFunctionality(t *testing.T) {
 
 	// Test Spanish translation
 	g.SetLanguage("es")
-	greeting, err = g.Greet()
+	greeting, err = g.Greet(context.Background())
 	if err != nil {
 		t.Errorf("Spanish greeting failed: %v", err)
 	}
@@ -101,7 +120,7 @@ func TestGreeterBasicFunctionality(t *testing.T) {
 
 	// Test cache functionality
 	g.SetLanguage("es")
-	greeting2, err := g.Greet()
+	greeting2, err := g.Greet(context.Background())
 	if err != nil {
 		t.Errorf("Cached Spanish greeting failed: %v", err)
 	}
@@ -128,8 +147,7 @@ func TestTimeBasedGreeting(t *testing.T) {
 	originalNow := timeNow
 	defer func() { timeNow = originalNow }()
 
-	g := newTestGreeter("Test", &mockTranslationClient{})
-	defer os.Remove(g.cacheFile)
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
 
 	for _, tt := range tests {
 		t.Run(tt.expected, func(t *testing.T) {
@@ -147,8 +165,7 @@ func TestTimeBasedGreeting(t *testing.T) {
 
 // TestSetLanguage tests language switching
 func TestSetLanguage(t *testing.T) {
-	g := newTestGreeter("Test", &mockTranslationClient{})
-	defer os.Remove(g.cacheFile)
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
 
 	languages := []Language{"en", "es", "fr", "ja"}
 	for _, lang := range languages {
@@ -161,38 +178,32 @@ func TestSetLanguage(t *testing.T) {
 
 // TestCachePersistence tests that translations are properly cached and loaded
 func TestCachePersistence(t *testing.T) {
-	// Create a temporary cache file
-	tmpfile, err := os.CreateTemp("", "translation_cache_*.json")
-	if err != nil {
-		t.Fatalf("Could not create temp file: %v", err)
-	}
-	defer os.Remove(tmpfile.Name())
+	cachePath := filepath.Join(t.TempDir(), "translation_cache.json")
 
 	// First greeter makes a translation
-	g1 := newTestGreeter("Test", &mockTranslationClient{})
-	g1.cacheFile = tmpfile.Name()
-	defer os.Remove(g1.cacheFile)
+	cache1, err := NewJSONCache(cachePath)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	g1 := newTestGreeter(t, "Test", &mockTranslationClient{})
+	g1.cache = cache1
 
 	g1.SetLanguage("es")
-	greeting1, err := g1.Greet()
+	greeting1, err := g1.Greet(context.Background())
 	if err != nil {
 		t.Fatalf("Initial translation failed: %v", err)
 	}
 
-	// Second greeter should load from cache
-	g2 := newTestGreeter("Test", &mockTranslationClient{})
-	g2.cacheFile = tmpfile.Name()
-	defer os.Remove(g2.cacheFile)
-
-	// Load cache from file
-	if data, err := os.ReadFile(tmpfile.Name()); err == nil {
-		if err := json.Unmarshal(data, &g2.cache.Translations); err != nil {
-			t.Fatalf("Failed to load cache: %v", err)
-		}
+	// Second greeter loads the same cache file from disk
+	cache2, err := NewJSONCache(cachePath)
+	if err != nil {
+		t.Fatalf("failed to reload cache: %v", err)
 	}
+	g2 := newTestGreeter(t, "Test", &mockTranslationClient{})
+	g2.cache = cache2
 
 	g2.SetLanguage("es")
-	greeting2, err := g2.Greet()
+	greeting2, err := g2.Greet(context.Background())
 	if err != nil {
 		t.Fatalf("Second translation failed: %v", err)
 	}
@@ -207,11 +218,10 @@ func TestCachePersistence(t *testing.T) {
 
 // TestErrorHandling tests various error conditions
 func TestErrorHandling(t *testing.T) {
-	g := newTestGreeter("Test", &mockTranslationClient{shouldError: true})
-	defer os.Remove(g.cacheFile)
+	g := newTestGreeter(t, "Test", &mockTranslationClient{shouldError: true})
 
 	g.SetLanguage("es")
-	_, err := g.Greet()
+	_, err := g.Greet(context.Background())
 	if err == nil {
 		t.Error("Expected error from translation service, got none")
 	}
@@ -219,11 +229,10 @@ func TestErrorHandling(t *testing.T) {
 
 // TestInvalidLanguageCode tests handling of unsupported language codes
 func TestInvalidLanguageCode(t *testing.T) {
-	g := newTestGreeter("Test", &mockTranslationClient{})
-	defer os.Remove(g.cacheFile)
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
 
 	g.SetLanguage("xx") // Invalid language code
-	_, err := g.Greet()
+	_, err := g.Greet(context.Background())
 	if err == nil {
 		t.Error("Expected error for invalid language code, got none")
 	}
@@ -231,11 +240,10 @@ func TestInvalidLanguageCode(t *testing.T) {
 
 // TestCostEstimation tests the cost estimation functionality
 func TestCostEstimation(t *testing.T) {
-	g := newTestGreeter("Test", &mockTranslationClient{})
-	defer os.Remove(g.cacheFile)
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
 
 	g.SetLanguage("es")
-	_, err := g.Greet()
+	_, err := g.Greet(context.Background())
 	if err != nil {
 		t.Fatalf("Greeting failed: %v", err)
 	}
@@ -253,15 +261,85 @@ func TestCostEstimation(t *testing.T) {
 	}
 }
 
+// TestGreetMany tests batch translation, order reconstruction, and stats.
+func TestGreetMany(t *testing.T) {
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
+
+	recipients := []string{"Alice", "Bob", "Carol"}
+	greetings, err := g.GreetMany(recipients, "es")
+	if err != nil {
+		t.Fatalf("GreetMany failed: %v", err)
+	}
+	if len(greetings) != len(recipients) {
+		t.Fatalf("expected %d greetings, got %d", len(recipients), len(greetings))
+	}
+	for i, greeting := range greetings {
+		if greeting == "" {
+			t.Errorf("greeting for %s was empty", recipients[i])
+		}
+	}
+
+	// All three greetings are distinct texts, so a single batched API call
+	// should cover all of them.
+	if g.stats.APICalls != 1 {
+		t.Errorf("expected 1 batched API call, got %d", g.stats.APICalls)
+	}
+
+	// Calling again should be served entirely from cache.
+	greetings2, err := g.GreetMany(recipients, "es")
+	if err != nil {
+		t.Fatalf("GreetMany (cached) failed: %v", err)
+	}
+	for i := range greetings {
+		if greetings[i] != greetings2[i] {
+			t.Errorf("cache mismatch for %s: got %q, want %q", recipients[i], greetings2[i], greetings[i])
+		}
+	}
+	if g.stats.CacheHits != len(recipients) {
+		t.Errorf("expected %d cache hits, got %d", len(recipients), g.stats.CacheHits)
+	}
+}
+
+// TestDetectSourceLanguage tests the DetectLanguage RPC wrapper.
+func TestDetectSourceLanguage(t *testing.T) {
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
+
+	lang, confidence, err := g.DetectSourceLanguage("Good morning, Test!")
+	if err != nil {
+		t.Fatalf("DetectSourceLanguage failed: %v", err)
+	}
+	if lang != "en" {
+		t.Errorf("expected detected language \"en\", got %q", lang)
+	}
+	if confidence <= 0 {
+		t.Errorf("expected positive confidence, got %f", confidence)
+	}
+}
+
+// TestAutoSourceGreeting tests that AutoSource mode still produces a
+// translation when the source language is left for the API to detect.
+func TestAutoSourceGreeting(t *testing.T) {
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
+	g.SetAutoSource(true)
+
+	g.SetLanguage("es")
+	greeting, err := g.Greet(context.Background())
+	if err != nil {
+		t.Fatalf("AutoSource greeting failed: %v", err)
+	}
+	if greeting == "" {
+		t.Error("AutoSource greeting was empty")
+	}
+}
+
 // TestMultipleLanguageTranslations tests translations across multiple languages
 func TestMultipleLanguageTranslations(t *testing.T) {
-	g := newTestGreeter("Test", &mockTranslationClient{})
-	defer os.Remove(g.cacheFile)
+	g := newTestGreeter(t, "Test", &mockTranslationClient{})
 
 	languages := []Language{"es", "fr", "ja", "de"}
 	for _, lang := range languages {
 		g.SetLanguage(lang)
-		greeting, err := g.Greet()
+		greeting, err := g.Greet(context.Background())
 		if err != nil {
 			t.Errorf("Translation failed for %s: %v", lang, err)
 		}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// supportedLanguages is the set of target languages the greeter is willing
+// to translate into. DetectLanguage and the CLI's locale fallback only ever
+// return languages from this set, falling back to English otherwise.
+var supportedLanguages = []Language{"en", "es", "fr", "de", "ja", "zh", "pt", "it", "ru", "ko"}
+
+// SupportedLanguages returns the registry of languages the greeter supports,
+// used to validate Accept-Language negotiation and system locale fallback.
+func SupportedLanguages() []Language {
+	langs := make([]Language, len(supportedLanguages))
+	copy(langs, supportedLanguages)
+	return langs
+}
+
+func isSupported(lang Language) bool {
+	for _, l := range supportedLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectLanguage picks the best supported language for an HTTP request by
+// parsing its Accept-Language header (including q-values). This mirrors how
+// i18n toolkits initialize a translation function from the caller's locale
+// rather than requiring an explicit language code.
+func DetectLanguage(r *http.Request) Language {
+	if lang, ok := bestMatch(r.Header.Get("Accept-Language")); ok {
+		return lang
+	}
+	return "en"
+}
+
+// bestMatch parses an Accept-Language header value and returns the
+// highest-weighted supported language, if any.
+func bestMatch(header string) (Language, bool) {
+	type candidate struct {
+		lang Language
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		tag := part
+		q := 1.0
+		if idx := strings.Index(part, ";"); idx != -1 {
+			tag = strings.TrimSpace(part[:idx])
+			if v, ok := strings.CutPrefix(strings.TrimSpace(part[idx+1:]), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		lang := Language(primaryTag(tag))
+		if lang == "*" || lang == "" {
+			continue
+		}
+		candidates = append(candidates, candidate{lang: lang, q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	for _, c := range candidates {
+		if isSupported(c.lang) {
+			return c.lang, true
+		}
+	}
+
+	return "", false
+}
+
+// primaryTag extracts the primary language subtag from a BCP 47 tag such as
+// "en-US" or a POSIX locale like "en_US.UTF-8", lowercased to match the
+// Language codes used elsewhere.
+func primaryTag(tag string) string {
+	tag = strings.ToLower(tag)
+	if idx := strings.IndexAny(tag, "-_."); idx != -1 {
+		tag = tag[:idx]
+	}
+	return tag
+}
+
+// DetectLocaleFromEnv mirrors DetectLanguage for CLI usage, falling back to
+// the process locale via $LC_ALL then $LANG (e.g. "en_US.UTF-8" -> "en")
+// when no language code was given on the command line.
+func DetectLocaleFromEnv() Language {
+	for _, envVar := range []string{"LC_ALL", "LANG"} {
+		value := os.Getenv(envVar)
+		if value == "" || value == "C" || value == "POSIX" {
+			continue
+		}
+		if lang := Language(primaryTag(value)); isSupported(lang) {
+			return lang
+		}
+	}
+	return "en"
+}
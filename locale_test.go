@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestDetectLanguage tests Accept-Language negotiation against the
+// supported-language registry.
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected Language
+	}{
+		{"", "en"},
+		{"es", "es"},
+		{"fr-CA", "fr"},
+		{"xx;q=0.9,de;q=0.5", "de"},
+		{"xx", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/greet", nil)
+			if tt.header != "" {
+				r.Header.Set("Accept-Language", tt.header)
+			}
+			if got := DetectLanguage(r); got != tt.expected {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.header, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestDetectLocaleFromEnv tests the $LC_ALL/$LANG fallback used by RunCLI.
+func TestDetectLocaleFromEnv(t *testing.T) {
+	tests := []struct {
+		lcAll    string
+		lang     string
+		expected Language
+	}{
+		{"", "", "en"},
+		{"", "es_ES.UTF-8", "es"},
+		{"fr_FR.UTF-8", "de_DE.UTF-8", "fr"},
+		{"C", "", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LANG", tt.lang)
+		if got := DetectLocaleFromEnv(); got != tt.expected {
+			t.Errorf("DetectLocaleFromEnv() with LC_ALL=%q LANG=%q = %q, want %q", tt.lcAll, tt.lang, got, tt.expected)
+		}
+	}
+}
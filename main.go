@@ -9,6 +9,7 @@ func main() {
 	// Define command-line flags
 	serverMode := flag.Bool("server", false, "Run in server mode")
 	port := flag.String("port", "8080", "Port to run the server on")
+	cacheBackend := flag.String("cache-backend", "json", "Translation cache backend: json, redis, or sqlite")
 	flag.Parse()
 
 	// Set port environment variable if specified
@@ -16,6 +17,11 @@ func main() {
 		os.Setenv("PORT", *port)
 	}
 
+	// Set cache backend environment variable if specified
+	if *cacheBackend != "json" {
+		os.Setenv("GREETER_CACHE_BACKEND", *cacheBackend)
+	}
+
 	if *serverMode {
 		// Run in server mode
 		RunServer()
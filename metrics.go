@@ -0,0 +1,94 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Prometheus metrics exported at /metrics, all labeled by target_language so
+// per-language cost and latency can be broken out in dashboards and alerts.
+var (
+	apiCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "greeter_api_calls_total",
+		Help: "Number of TranslateText API calls made.",
+	}, []string{"target_language"})
+
+	charsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "greeter_chars_sent_total",
+		Help: "Number of characters sent to the Translate API.",
+	}, []string{"target_language"})
+
+	costUSDTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "greeter_cost_usd_total",
+		Help: "Estimated translation cost in USD.",
+	}, []string{"target_language"})
+
+	cacheHitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "greeter_cache_hits_total",
+		Help: "Number of translation cache hits.",
+	}, []string{"target_language"})
+
+	cacheMissesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "greeter_cache_misses_total",
+		Help: "Number of translation cache misses.",
+	}, []string{"target_language"})
+
+	translationLatencySeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "greeter_translation_latency_seconds",
+		Help: "Latency of translateGreetingCtx calls made on a cache miss, excluding the cache lookup.",
+	}, []string{"target_language"})
+)
+
+// languageStatsRegistry aggregates Stats per target language for the
+// /api/stats JSON endpoint, replacing the single ad-hoc globalStats struct
+// server.go used to keep.
+type languageStatsRegistry struct {
+	mu     sync.RWMutex
+	byLang map[string]*Stats
+}
+
+var globalStats = &languageStatsRegistry{byLang: make(map[string]*Stats)}
+
+// recordRequestStats folds a single request's Stats into both the
+// per-language registry and the Prometheus counters above.
+func recordRequestStats(targetLang string, newStats *Stats) {
+	if newStats == nil {
+		return
+	}
+
+	apiCallsTotal.WithLabelValues(targetLang).Add(float64(newStats.APICalls))
+	charsSentTotal.WithLabelValues(targetLang).Add(float64(newStats.CharsSent))
+	costUSDTotal.WithLabelValues(targetLang).Add(newStats.CostEstimate)
+	cacheHitsTotal.WithLabelValues(targetLang).Add(float64(newStats.CacheHits))
+	cacheMissesTotal.WithLabelValues(targetLang).Add(float64(newStats.CacheMisses))
+
+	globalStats.mu.Lock()
+	defer globalStats.mu.Unlock()
+
+	stats, ok := globalStats.byLang[targetLang]
+	if !ok {
+		stats = &Stats{}
+		globalStats.byLang[targetLang] = stats
+	}
+	stats.APICalls += newStats.APICalls
+	stats.CharsSent += newStats.CharsSent
+	stats.CostEstimate += newStats.CostEstimate
+	stats.CacheHits += newStats.CacheHits
+	stats.CacheMisses += newStats.CacheMisses
+	stats.CacheBytes = newStats.CacheBytes
+}
+
+// statsSnapshot returns a copy of the per-language stats registry, safe to
+// serialize without holding the registry's lock.
+func statsSnapshot() map[string]Stats {
+	globalStats.mu.RLock()
+	defer globalStats.mu.RUnlock()
+
+	snapshot := make(map[string]Stats, len(globalStats.byLang))
+	for lang, stats := range globalStats.byLang {
+		snapshot[lang] = *stats
+	}
+	return snapshot
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+// TestRecordRequestStats tests that per-request stats accumulate correctly
+// in the per-language registry backing /api/stats.
+func TestRecordRequestStats(t *testing.T) {
+	recordRequestStats("es", &Stats{APICalls: 1, CharsSent: 20, CostEstimate: 0.0004, CacheHits: 1})
+	recordRequestStats("es", &Stats{APICalls: 1, CharsSent: 10, CostEstimate: 0.0002, CacheMisses: 1})
+
+	snapshot := statsSnapshot()
+	stats, ok := snapshot["es"]
+	if !ok {
+		t.Fatal("expected \"es\" entry in stats snapshot")
+	}
+	if stats.APICalls != 2 {
+		t.Errorf("expected 2 APICalls, got %d", stats.APICalls)
+	}
+	if stats.CharsSent != 30 {
+		t.Errorf("expected 30 CharsSent, got %d", stats.CharsSent)
+	}
+	if stats.CacheHits != 1 || stats.CacheMisses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %d hits, %d misses", stats.CacheHits, stats.CacheMisses)
+	}
+}
@@ -2,16 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"sync"
-)
 
-// Global stats for the server
-var (
-	globalStats Stats
-	statsMutex  sync.RWMutex
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type GreetingRequest struct {
@@ -24,19 +20,22 @@ type GreetingResponse struct {
 	Stats    *Stats `json:"stats,omitempty"`
 }
 
-// updateGlobalStats updates the global stats with the latest translation stats
-func updateGlobalStats(newStats *Stats) {
-	if newStats == nil {
-		return
-	}
+// BatchGreetingRequest is the body for POST /api/greet/batch.
+type BatchGreetingRequest struct {
+	Names    []string `json:"names"`
+	Language string   `json:"language"`
+}
 
-	statsMutex.Lock()
-	defer statsMutex.Unlock()
+// BatchGreetingResponse holds one greeting per requested name, in order.
+type BatchGreetingResponse struct {
+	Greetings []string `json:"greetings"`
+	Stats     *Stats   `json:"stats,omitempty"`
+}
 
-	globalStats.APICalls += newStats.APICalls
-	globalStats.CharsSent += newStats.CharsSent
-	globalStats.CostEstimate += newStats.CostEstimate
-	globalStats.CacheHits += newStats.CacheHits
+// DetectResponse is the body for GET /api/detect.
+type DetectResponse struct {
+	Language   string  `json:"language"`
+	Confidence float32 `json:"confidence"`
 }
 
 func corsMiddleware(next http.Handler) http.Handler {
@@ -64,11 +63,17 @@ func RunServer() {
 		name := r.URL.Query().Get("name")
 		language := r.URL.Query().Get("language")
 
-		if name == "" || language == "" {
-			http.Error(w, "Missing name or language parameter", http.StatusBadRequest)
+		if name == "" {
+			http.Error(w, "Missing name parameter", http.StatusBadRequest)
 			return
 		}
 
+		// Fall back to the caller's Accept-Language header when no explicit
+		// language query parameter was given.
+		if language == "" {
+			language = string(DetectLanguage(r))
+		}
+
 		greeter, err := NewGreeter(name)
 		if err != nil {
 			http.Error(w, "Failed to create greeter: "+err.Error(), http.StatusInternalServerError)
@@ -77,14 +82,14 @@ func RunServer() {
 		defer greeter.Close()
 
 		greeter.SetLanguage(Language(language))
-		greeting, err := greeter.Greet()
+		greeting, err := greeter.Greet(r.Context())
 		if err != nil {
 			http.Error(w, "Failed to get greeting: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		// Update global stats with the latest translation stats
-		updateGlobalStats(greeter.stats)
+		// Record stats into Prometheus and the per-language /api/stats registry
+		recordRequestStats(language, greeter.stats)
 
 		response := GreetingResponse{
 			Greeting: greeting,
@@ -101,6 +106,134 @@ func RunServer() {
 		json.NewEncoder(w).Encode(response)
 	})
 
+	// Batch API endpoint: translates greetings for many recipients in as few
+	// TranslateText calls as possible.
+	http.HandleFunc("/api/greet/batch", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req BatchGreetingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if len(req.Names) == 0 || req.Language == "" {
+			http.Error(w, "Missing names or language", http.StatusBadRequest)
+			return
+		}
+
+		greeter, err := NewGreeter(req.Names[0])
+		if err != nil {
+			http.Error(w, "Failed to create greeter: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer greeter.Close()
+
+		greetings, err := greeter.GreetMany(req.Names, Language(req.Language))
+		if err != nil {
+			http.Error(w, "Failed to get greetings: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Record stats into Prometheus and the per-language /api/stats registry
+		recordRequestStats(req.Language, greeter.stats)
+
+		response := BatchGreetingResponse{Greetings: greetings}
+
+		// Only include stats if there was an API call or cache hit
+		if greeter.stats.APICalls > 0 || greeter.stats.CacheHits > 0 {
+			response.Stats = greeter.stats
+			log.Printf("Batch stats: calls=%d, chars=%d, cost=%.5f, hits=%d",
+				greeter.stats.APICalls, greeter.stats.CharsSent, greeter.stats.CostEstimate, greeter.stats.CacheHits)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+
+	// Streams a single greeting request's FSM transitions as Server-Sent
+	// Events, so a slow or retried translation is observable instead of a
+	// blocking call that just eventually returns.
+	http.HandleFunc("/api/greet/stream", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		language := r.URL.Query().Get("language")
+
+		if name == "" {
+			http.Error(w, "Missing name parameter", http.StatusBadRequest)
+			return
+		}
+		if language == "" {
+			language = string(DetectLanguage(r))
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		greeter, err := NewGreeter(name)
+		if err != nil {
+			http.Error(w, "Failed to create greeter: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer greeter.Close()
+		greeter.SetLanguage(Language(language))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		fsm := greeter.GreetStream(r.Context())
+		for t := range fsm.Events {
+			data, err := json.Marshal(t)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+
+		recordRequestStats(language, greeter.stats)
+	})
+
+	// Detects the source language of arbitrary text.
+	http.HandleFunc("/api/detect", func(w http.ResponseWriter, r *http.Request) {
+		text := r.URL.Query().Get("text")
+		if text == "" {
+			http.Error(w, "Missing text parameter", http.StatusBadRequest)
+			return
+		}
+
+		greeter, err := NewGreeter("detect")
+		if err != nil {
+			http.Error(w, "Failed to create greeter: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer greeter.Close()
+
+		language, confidence, err := greeter.DetectSourceLanguage(text)
+		if err != nil {
+			http.Error(w, "Failed to detect language: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DetectResponse{Language: string(language), Confidence: confidence})
+	})
+
+	// Prometheus scrape endpoint
+	http.Handle("/metrics", promhttp.Handler())
+
+	// Per-language JSON breakdown of the same stats exposed via Prometheus
+	http.HandleFunc("/api/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(statsSnapshot())
+	})
+
 	// Wrap all handlers with CORS middleware
 	handler := corsMiddleware(http.DefaultServeMux)
 